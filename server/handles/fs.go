@@ -0,0 +1,102 @@
+package handles
+
+import (
+	"path"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type FsMoveReq struct {
+	SrcDir string   `json:"src_dir" binding:"required"`
+	DstDir string   `json:"dst_dir" binding:"required"`
+	Names  []string `json:"names" binding:"required"`
+}
+
+// FsMove implements POST /api/fs/move. Every source and destination path is
+// checked against the lock table before anything moves: a path locked by
+// someone else's token aborts the whole batch with 423, using the same
+// op.ErrLocked handling as FsLock/FsUnlock.
+func FsMove(c *gin.Context) {
+	var req FsMoveReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	ifToken := c.GetHeader("If")
+	for _, name := range req.Names {
+		srcPath := path.Join(req.SrcDir, name)
+		dstPath := path.Join(req.DstDir, name)
+		if !checkPathAccess(c, srcPath) || !checkPathAccess(c, dstPath) {
+			return
+		}
+		if !CheckFsLock(c, srcPath, ifToken) || !CheckFsLock(c, dstPath, ifToken) {
+			return
+		}
+	}
+	for _, name := range req.Names {
+		srcPath := path.Join(req.SrcDir, name)
+		dstPath := path.Join(req.DstDir, name)
+		if err := fs.Move(c.Request.Context(), srcPath, dstPath); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
+
+type FsRemoveReq struct {
+	Dir   string   `json:"dir" binding:"required"`
+	Names []string `json:"names" binding:"required"`
+}
+
+// FsRemove implements POST /api/fs/remove, locked the same way as FsMove.
+func FsRemove(c *gin.Context) {
+	var req FsRemoveReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	ifToken := c.GetHeader("If")
+	for _, name := range req.Names {
+		p := path.Join(req.Dir, name)
+		if !checkPathAccess(c, p) {
+			return
+		}
+		if !CheckFsLock(c, p, ifToken) {
+			return
+		}
+	}
+	for _, name := range req.Names {
+		p := path.Join(req.Dir, name)
+		if err := fs.Remove(c.Request.Context(), p); err != nil {
+			common.ErrorResp(c, err, 500)
+			return
+		}
+	}
+	common.SuccessResp(c)
+}
+
+// FsPut implements PUT /api/fs/put. The target path travels in the
+// File-Path request header, same as the rest of this API's upload route;
+// the lock check happens before the body is read so a locked destination
+// never gets a partial write.
+func FsPut(c *gin.Context) {
+	p := c.GetHeader("File-Path")
+	if p == "" {
+		common.ErrorStrResp(c, "File-Path header is required", 400)
+		return
+	}
+	if !checkPathAccess(c, p) {
+		return
+	}
+	if !CheckFsLock(c, p, c.GetHeader("If")) {
+		return
+	}
+	if err := fs.Put(c.Request.Context(), p, c.Request.Body, c.Request.ContentLength); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
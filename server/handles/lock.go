@@ -0,0 +1,163 @@
+package handles
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type LockReq struct {
+	Path  string `json:"path" binding:"required"`
+	Depth string `json:"depth"`
+	// TimeoutSeconds is the requested lock lifetime; 0 means the server default.
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+type RefreshLockReq struct {
+	Path  string `json:"path" binding:"required"`
+	Token string `json:"token" binding:"required"`
+}
+
+type UnlockReq struct {
+	Path  string `json:"path" binding:"required"`
+	Token string `json:"token" binding:"required"`
+}
+
+// checkPathAccess applies the same BasePath rule every other fs/storage
+// handler in this package enforces: admins can touch any path, everyone
+// else is confined to their own BasePath.
+func checkPathAccess(c *gin.Context, p string) bool {
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if user.IsAdmin() {
+		return true
+	}
+	if !strings.HasPrefix(p, user.BasePath) {
+		common.ErrorStrResp(c, "permission denied: you can only operate under "+user.BasePath, 403)
+		return false
+	}
+	return true
+}
+
+// FsLock implements POST /api/fs/lock. It is the JSON-API counterpart of
+// WebDAV LOCK: it takes out an application-level, storage-scoped lock that
+// FsMove/FsRemove/FsPut check through CheckFsLock before touching a path.
+func FsLock(c *gin.Context) {
+	var req LockReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !checkPathAccess(c, req.Path) {
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	storage, actualPath, err := op.GetStorageAndActualPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	lock, err := op.SetLock(c.Request.Context(), storage, actualPath, op.LockOwner{
+		User:  user.Username,
+		Depth: req.Depth,
+	}, req.TimeoutSeconds)
+	if err != nil {
+		if errIsLocked(err) {
+			common.ErrorResp(c, err, 423)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, lock)
+}
+
+// FsRefreshLock implements POST /api/fs/refresh_lock, extending the
+// expiry of a lock the caller already holds the token for.
+func FsRefreshLock(c *gin.Context) {
+	var req RefreshLockReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !checkPathAccess(c, req.Path) {
+		return
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	lock, err := op.RefreshLock(c.Request.Context(), storage, actualPath, req.Token)
+	if err != nil {
+		if errIsLocked(err) {
+			common.ErrorResp(c, err, 423)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, lock)
+}
+
+// FsUnlock implements DELETE /api/fs/lock.
+func FsUnlock(c *gin.Context) {
+	var req UnlockReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if !checkPathAccess(c, req.Path) {
+		return
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := op.Unlock(c.Request.Context(), storage, actualPath, req.Token); err != nil {
+		if errIsLocked(err) {
+			common.ErrorResp(c, err, 423)
+			return
+		}
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func errIsLocked(err error) bool {
+	var locked op.ErrLocked
+	return errors.As(err, &locked)
+}
+
+// CheckFsLock enforces the lock table against a mutating fs operation. It is
+// the shared gate FsMove/FsRemove/FsPut (fs.go) call before touching a path:
+// a path locked by someone else's token is rejected with 423, mirroring the
+// same op.ErrLocked handling as the /api/fs/lock family above. ifToken is
+// the caller's own lock token, if any (the raw If: request header) and is
+// allowed through.
+//
+// The WebDAV server (server/webdav) does not call this guard: its
+// LOCK/UNLOCK and If: header handling, and the cross-space copy+delete in
+// ServeSpace, do not consult it.
+func CheckFsLock(c *gin.Context, path string, ifToken string) bool {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return false
+	}
+	if err := op.CheckLock(c.Request.Context(), storage, actualPath, ifToken); err != nil {
+		if errIsLocked(err) {
+			common.ErrorResp(c, err, 423)
+			return false
+		}
+		common.ErrorResp(c, err, 500)
+		return false
+	}
+	return true
+}
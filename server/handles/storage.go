@@ -6,6 +6,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/OpenListTeam/OpenList/v4/internal/conf"
@@ -30,7 +31,7 @@ type detailWithIndex struct {
 	val *model.StorageDetails
 }
 
-func makeStorageResp(ctx *gin.Context, storages []model.Storage) []*StorageResp {
+func makeStorageResp(ctx *gin.Context, storages []model.Storage, mask *FieldMask) []*StorageResp {
 	ret := make([]*StorageResp, len(storages))
 	detailsChan := make(chan detailWithIndex, len(storages))
 	workerCount := 0
@@ -38,6 +39,8 @@ func makeStorageResp(ctx *gin.Context, storages []model.Storage) []*StorageResp
 	if val := ctx.Request.Context().Value(conf.UserKey); val != nil {
 		user = val.(*model.User)
 	}
+	fetchDetails := wantDetails(ctx, mask, setting.GetBool(conf.HideStorageDetailsInManagePage))
+	timeout := time.Duration(detailsTimeout(ctx, 3000)) * time.Millisecond
 
 	for i, s := range storages {
 		// Strip BasePath for non-admin users to show relative path
@@ -52,7 +55,7 @@ func makeStorageResp(ctx *gin.Context, storages []model.Storage) []*StorageResp
 			Storage:      s,
 			MountDetails: nil,
 		}
-		if setting.GetBool(conf.HideStorageDetailsInManagePage) {
+		if !fetchDetails {
 			continue
 		}
 		d, err := op.GetStorageByMountPath(s.MountPath)
@@ -79,7 +82,7 @@ func makeStorageResp(ctx *gin.Context, storages []model.Storage) []*StorageResp
 		case r := <-detailsChan:
 			ret[r.idx].MountDetails = r.val
 			workerCount--
-		case <-time.After(time.Second * 3):
+		case <-time.After(timeout):
 			workerCount = 0
 		}
 	}
@@ -99,8 +102,10 @@ func ListStorages(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	mask := parseFieldMask(c)
+	resp := makeStorageResp(c, filterStorages(c, storages), mask)
 	common.SuccessResp(c, common.PageResp{
-		Content: makeStorageResp(c, filterStorages(c, storages)),
+		Content: projectStorageResps(resp, mask),
 		Total:   total, // TODO: Total is wrong here if filtered, but UI might not care much or we fix total later. Filtered count is better.
 	})
 }
@@ -116,7 +121,6 @@ func filterStorages(c *gin.Context, storages []model.Storage) []model.Storage {
 	}
 	var ret []model.Storage
 	for _, s := range storages {
-		log.Infof("Debug Filter: User=%s BasePath=%s Storage=%s Match=%v", u.Username, u.BasePath, s.MountPath, strings.HasPrefix(s.MountPath, u.BasePath))
 		if strings.HasPrefix(s.MountPath, u.BasePath) {
 			ret = append(ret, s)
 		}
@@ -131,7 +135,19 @@ func CreateStorage(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
-	log.Infof("Debug CreateStorage: User=%s IsAdmin=%v MountPath=%s BasePath=%s", user.Username, user.IsAdmin(), req.MountPath, user.BasePath)
+
+	// A template_id lets the caller attach an already-configured, named
+	// driver config (e.g. a shared S3 bucket) at a new mount path instead
+	// of pasting the Addition again. The BasePath check below still only
+	// ever applies to the mount path, never to the template itself.
+	if err := resolveStorageFromTemplate(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := resolveStorageFromCredential(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 
 	if !user.IsAdmin() {
 		if !strings.HasPrefix(req.MountPath, user.BasePath) {
@@ -145,7 +161,7 @@ func CreateStorage(c *gin.Context) {
 		}
 	}
 	if id, err := op.CreateStorage(c.Request.Context(), req); err != nil {
-		log.Errorf("Debug CreateStorage Failed: %v", err)
+		log.Errorf("failed to create storage: %v", err)
 		common.ErrorWithDataResp(c, err, 500, gin.H{
 			"id": id,
 		}, true)
@@ -163,6 +179,14 @@ func UpdateStorage(c *gin.Context) {
 		return
 	}
 	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if err := resolveStorageFromTemplate(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err := resolveStorageFromCredential(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
 	if !user.IsAdmin() {
 		// Check existing storage first to ensure user owns it
 		oldS, err := db.GetStorageById(req.ID)
@@ -184,6 +208,10 @@ func UpdateStorage(c *gin.Context) {
 			return
 		}
 	}
+	if op.HasActiveLocks(req.MountPath) {
+		common.ErrorStrResp(c, "storage is locked: "+req.MountPath, 423)
+		return
+	}
 	if err := op.UpdateStorage(c.Request.Context(), req); err != nil {
 		common.ErrorResp(c, err, 500, true)
 	} else {
@@ -206,6 +234,10 @@ func DeleteStorage(c *gin.Context) {
 			return
 		}
 	}
+	if s, err := db.GetStorageById(uint(id)); err == nil && op.HasActiveLocks(s.MountPath) {
+		common.ErrorStrResp(c, "storage is locked: "+s.MountPath, 423)
+		return
+	}
 	if err := op.DeleteStorageById(c.Request.Context(), uint(id)); err != nil {
 		common.ErrorResp(c, err, 500, true)
 		return
@@ -287,26 +319,13 @@ func GetStorage(c *gin.Context) {
 	common.SuccessResp(c, storage)
 }
 
-// Old GetStorage implementation below was:
-// 	storage, err := db.GetStorageById(uint(id))
-// 	if err != nil {
-// 		common.ErrorResp(c, err, 500, true)
-// 		return
-// 	}
-// 	common.SuccessResp(c, storage)
-// }
-// I replaced it with the above valid function.
-// Wait, I need to match the original function signature/body to replace it correctly.
-// The original:
-// 	storage, err := db.GetStorageById(uint(id))
-// 	if err != nil {
-// 		common.ErrorResp(c, err, 500, true)
-// 		return
-// 	}
-// 	common.SuccessResp(c, storage)
-// }
-
-
+// LoadAllStorages kicks off a reload of every enabled storage on a bounded
+// worker pool (conf.MaxConcurrentStorageLoads, default runtime.NumCPU()) and
+// returns immediately; callers poll progress via GetStoragesLoadStatus or
+// stream it via StoragesLoadEvents. Drivers that declare WithSerialInit
+// (cloud drivers that rate-limit auth) are funneled through a single
+// worker so they never run concurrently with one another, while everything
+// else fans out across the pool.
 func LoadAllStorages(c *gin.Context) {
 	storages, err := db.GetEnabledStorages()
 	if err != nil {
@@ -315,26 +334,70 @@ func LoadAllStorages(c *gin.Context) {
 		return
 	}
 	conf.ResetStoragesLoadSignal()
-	go func(storages []model.Storage) {
-		for _, storage := range storages {
-			storageDriver, err := op.GetStorageByMountPath(storage.MountPath)
-			if err != nil {
-				log.Errorf("failed get storage driver: %+v", err)
-				continue
-			}
-			// drop the storage in the driver
-			if err := storageDriver.Drop(context.Background()); err != nil {
-				log.Errorf("failed drop storage: %+v", err)
-				continue
-			}
-			if err := op.LoadStorage(context.Background(), storage); err != nil {
-				log.Errorf("failed get enabled storages: %+v", err)
+	op.LoadStates.Reset(storages)
+
+	parallel := make([]model.Storage, 0, len(storages))
+	serial := make([]model.Storage, 0)
+	for _, storage := range storages {
+		// Classify by the registered driver constructor, not a live
+		// instance: op.GetStorageByMountPath errors for a storage that
+		// hasn't been loaded yet (e.g. cold start), which would silently
+		// misclassify a WithSerialInit cloud driver as safe to parallelize.
+		if d, err := op.GetDriver(storage.Driver); err == nil {
+			if _, ok := d.(driver.WithSerialInit); ok {
+				serial = append(serial, storage)
 				continue
 			}
-			log.Infof("success load storage: [%s], driver: [%s]",
-				storage.MountPath, storage.Driver)
 		}
+		parallel = append(parallel, storage)
+	}
+
+	go func() {
+		workers := conf.MaxConcurrentStorageLoads()
+		if workers < 1 {
+			workers = 1
+		}
+		sem := make(chan struct{}, workers)
+		var wg sync.WaitGroup
+		for _, storage := range parallel {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(storage model.Storage) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				loadOneStorage(storage)
+			}(storage)
+		}
+		for _, storage := range serial {
+			loadOneStorage(storage)
+		}
+		wg.Wait()
 		conf.SendStoragesLoadedSignal()
-	}(storages)
+	}()
 	common.SuccessResp(c)
 }
+
+func loadOneStorage(storage model.Storage) {
+	start := time.Now()
+	op.LoadStates.Set(storage.ID, op.LoadStateLoading, "")
+	storageDriver, err := op.GetStorageByMountPath(storage.MountPath)
+	if err != nil {
+		log.Errorf("failed get storage driver: %+v", err)
+		op.LoadStates.SetError(storage.ID, err, time.Since(start))
+		return
+	}
+	// drop the storage in the driver
+	if err := storageDriver.Drop(context.Background()); err != nil {
+		log.Errorf("failed drop storage: %+v", err)
+		op.LoadStates.SetError(storage.ID, err, time.Since(start))
+		return
+	}
+	if err := op.LoadStorage(context.Background(), storage); err != nil {
+		log.Errorf("failed get enabled storages: %+v", err)
+		op.LoadStates.SetError(storage.ID, err, time.Since(start))
+		return
+	}
+	log.Infof("success load storage: [%s], driver: [%s]",
+		storage.MountPath, storage.Driver)
+	op.LoadStates.SetOk(storage.ID, time.Since(start))
+}
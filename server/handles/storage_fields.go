@@ -0,0 +1,131 @@
+package handles
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// FieldMask is a sparse projection requested via ?fields=id,mount_path,...
+// on ListStorages; GetStorage does not apply it. A nil mask (no fields param) means "return
+// everything", matching today's behavior.
+type FieldMask struct {
+	fields map[string]bool
+}
+
+// wantsDetails reports whether any mount_details.* sub-field was requested,
+// so the caller can skip the op.GetStorageDetails round trip entirely when
+// it wasn't.
+func (m *FieldMask) wantsDetails() bool {
+	if m == nil || m.fields == nil {
+		return true
+	}
+	for f := range m.fields {
+		if f == "mount_details" || strings.HasPrefix(f, "mount_details.") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFieldMask(c *gin.Context) *FieldMask {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil
+	}
+	fields := make(map[string]bool)
+	for _, f := range strings.Split(raw, ",") {
+		f = strings.TrimSpace(f)
+		if f != "" {
+			fields[f] = true
+		}
+	}
+	return &FieldMask{fields: fields}
+}
+
+// wantDetails resolves whether this request should fetch mount_details at
+// all: an explicit, parseable details=true or details=false always wins,
+// otherwise it falls back to the field mask (if any), then the server-global
+// HideStorageDetailsInManagePage.
+func wantDetails(c *gin.Context, mask *FieldMask, globalHide bool) bool {
+	if v := c.Query("details"); v != "" {
+		if want, err := strconv.ParseBool(v); err == nil {
+			return want
+		}
+	}
+	if !mask.wantsDetails() {
+		return false
+	}
+	return !globalHide
+}
+
+// projectStorageResps applies the field mask to each response by round
+// tripping through JSON: top-level fields not requested are dropped, and
+// mount_details.* sub-fields are pruned the same way. A nil mask is a
+// no-op so the common, unfiltered path pays nothing extra.
+func projectStorageResps(resps []*StorageResp, mask *FieldMask) []interface{} {
+	ret := make([]interface{}, len(resps))
+	if mask == nil || mask.fields == nil {
+		for i, r := range resps {
+			ret[i] = r
+		}
+		return ret
+	}
+	for i, r := range resps {
+		ret[i] = projectOne(r, mask)
+	}
+	return ret
+}
+
+func projectOne(r *StorageResp, mask *FieldMask) map[string]interface{} {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		log.Errorf("failed to marshal storage resp for field projection: %+v", err)
+		return nil
+	}
+	var full map[string]interface{}
+	if err := json.Unmarshal(raw, &full); err != nil {
+		log.Errorf("failed to unmarshal storage resp for field projection: %+v", err)
+		return nil
+	}
+	out := make(map[string]interface{}, len(mask.fields))
+	for field := range mask.fields {
+		top, sub, hasSub := strings.Cut(field, ".")
+		v, ok := full[top]
+		if !ok {
+			continue
+		}
+		if !hasSub {
+			out[top] = v
+			continue
+		}
+		nested, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dst, ok := out[top].(map[string]interface{})
+		if !ok {
+			dst = make(map[string]interface{})
+			out[top] = dst
+		}
+		if nv, ok := nested[sub]; ok {
+			dst[sub] = nv
+		}
+	}
+	return out
+}
+
+func detailsTimeout(c *gin.Context, def int) int {
+	v := c.Query("details_timeout_ms")
+	if v == "" {
+		return def
+	}
+	ms, err := strconv.Atoi(v)
+	if err != nil || ms <= 0 {
+		return def
+	}
+	return ms
+}
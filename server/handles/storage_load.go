@@ -0,0 +1,45 @@
+package handles
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+// GetStoragesLoadStatus implements GET /api/admin/storage/load_status,
+// a one-shot poll over the per-storage load state left behind by the
+// most recent LoadAllStorages run.
+func GetStoragesLoadStatus(c *gin.Context) {
+	common.SuccessResp(c, op.LoadStates.Snapshot())
+}
+
+// StoragesLoadEvents implements GET /api/admin/storage/load_events, an SSE
+// stream of load state transitions for the in-flight LoadAllStorages run.
+// It closes once SendStoragesLoadedSignal fires or the client disconnects.
+func StoragesLoadEvents(c *gin.Context) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+
+	sub := op.LoadStates.Subscribe()
+	defer op.LoadStates.Unsubscribe(sub)
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case state, ok := <-sub:
+			if !ok {
+				return false
+			}
+			fmt.Fprintf(w, "event: state\ndata: %s\n\n", state.JSON())
+			return true
+		case <-conf.StoragesLoaded:
+			return false
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package handles
+
+import (
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/conf"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/internal/op"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListOAuthProviders implements GET /api/admin/storage/oauth/providers,
+// returning the drivers that opted in by implementing driver.OAuthProvider.
+func ListOAuthProviders(c *gin.Context) {
+	common.SuccessResp(c, op.GetOAuthProviders())
+}
+
+type OAuthStartReq struct {
+	Driver string `json:"driver" binding:"required"`
+	// MountPath is the mount the resulting credential is ultimately destined
+	// for. It is used to pre-check a non-admin caller's BasePath and is
+	// encoded into the signed state; the actual storage is still created
+	// afterwards via CreateStorage.
+	MountPath string `json:"mount_path" binding:"required"`
+}
+
+// StartOAuth implements POST /api/admin/storage/oauth/start. It returns a
+// state-signed authorize URL for the chosen driver; the state encodes the
+// driver name and mount path so the callback can validate both again.
+func StartOAuth(c *gin.Context) {
+	var req OAuthStartReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.Request.Context().Value(conf.UserKey).(*model.User)
+	if !user.IsAdmin() && !pathUnderBase(req.MountPath, user.BasePath) {
+		common.ErrorStrResp(c, "permission denied: you can only mount under "+user.BasePath, 403)
+		return
+	}
+	url, err := op.StartOAuth(req.Driver, req.MountPath)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"url": url,
+	})
+}
+
+// OAuthCallback implements GET /api/admin/storage/oauth/callback. It is a
+// top-level browser redirect from the provider, so it carries no OpenList
+// session to re-check — the non-admin BasePath check already happened once,
+// against the authenticated caller, in StartOAuth. The callback instead
+// trusts the signed state it's handed back (state was minted for exactly
+// this driver/mount_path pair and can't be forged or replayed for another
+// one), exchanges the code, persists the token in the oauth_credentials
+// table, and hands back a credential_id that CreateStorage/UpdateStorage
+// accept in place of a raw refresh token.
+func OAuthCallback(c *gin.Context) {
+	state := c.Query("state")
+	code := c.Query("code")
+	if _, err := op.ValidateOAuthState(state); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	credential, err := op.ExchangeOAuthCode(c.Request.Context(), state, code)
+	if err != nil {
+		log.Errorf("failed to exchange oauth code: %+v", err)
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"credential_id": credential.ID,
+	})
+}
+
+// resolveStorageFromCredential validates a previously exchanged OAuth
+// credential when the caller passed a credential_id instead of a raw
+// refresh token. It deliberately does NOT bake the token into
+// req.Addition: the mount is persisted with CredentialId set, and
+// op.LoadStorage resolves CredentialId -> the current token via the
+// oauth_credentials table at driver-init time, so a background token
+// refresh transparently applies to every storage that references it.
+func resolveStorageFromCredential(req *model.Storage) error {
+	if req.CredentialId == 0 {
+		return nil
+	}
+	if _, err := op.GetOAuthCredential(req.Driver, req.CredentialId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func pathUnderBase(p, base string) bool {
+	if base == "" || base == "/" {
+		return true
+	}
+	return strings.HasPrefix(p, base)
+}
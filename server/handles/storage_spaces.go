@@ -0,0 +1,48 @@
+package handles
+
+import (
+	"strconv"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/OpenListTeam/OpenList/v4/server/common"
+	"github.com/OpenListTeam/OpenList/v4/server/webdav"
+	"github.com/gin-gonic/gin"
+)
+
+type SpaceResp struct {
+	Id        uint   `json:"id"`
+	MountPath string `json:"mount_path"`
+	Driver    string `json:"driver"`
+	// DavPath is the bookmarkable per-storage DAV root served by
+	// server/webdav.ServeSpace, as opposed to the unified /dav/ tree.
+	DavPath string `json:"dav_path"`
+}
+
+// FsSpaces implements GET /api/fs/spaces, listing every storage visible to
+// the caller — each as an independent DAV root at /dav/spaces/{id}/... —
+// under the same filterStorages/BasePath rules as ListStorages.
+func FsSpaces(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	storages, _, err := db.GetStorages(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	visible := filterStorages(c, storages)
+	ret := make([]SpaceResp, 0, len(visible))
+	for _, s := range visible {
+		ret = append(ret, SpaceResp{
+			Id:        s.ID,
+			MountPath: s.MountPath,
+			Driver:    s.Driver,
+			DavPath:   webdav.SpacesPrefix + strconv.FormatUint(uint64(s.ID), 10),
+		})
+	}
+	common.SuccessResp(c, ret)
+}
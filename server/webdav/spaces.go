@@ -0,0 +1,150 @@
+// Package webdav extends the unified /dav/ tree with a per-storage DAV
+// root at /dav/spaces/{storage_id}/..., so a client can bookmark one mount
+// instead of always browsing down from the tree root.
+package webdav
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/OpenListTeam/OpenList/v4/internal/db"
+	"github.com/OpenListTeam/OpenList/v4/internal/fs"
+	"github.com/OpenListTeam/OpenList/v4/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// SpacesPrefix is the route prefix every /dav/spaces/{id}/... request is
+// mounted under.
+const SpacesPrefix = "/dav/spaces/"
+
+// ServeSpace is the entry point for everything under SpacesPrefix. It
+// resolves {storage_id} to a model.Storage row and then special-cases the
+// two behaviors the unified /dav/ tree doesn't need:
+//
+//  1. A Depth: 0 PROPFIND against the space root itself is synthesized straight from
+//     the model.Storage row, without walking the virtual FS or resolving a
+//     global path first (mirrors Reva's "skip space lookup on space
+//     propfind" optimization, and keeps one slow/broken mount from stalling
+//     a client that only bookmarked a different one).
+//  2. COPY/MOVE whose Destination header targets a different space ID is
+//     degraded to a server-side copy+delete instead of being rejected,
+//     since the two spaces may not share a common virtual-FS ancestor that
+//     a single driver-level rename could use.
+//
+// Everything else is rewritten onto the equivalent unified-tree path and
+// left for the shared DAV handler to serve, so CRUD, range requests, lock
+// tokens, etc. keep exactly one implementation.
+func ServeSpace(c *gin.Context) {
+	id, subPath, err := parseSpacePath(c.Request.URL.Path)
+	if err != nil {
+		http.Error(c.Writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	storage, err := db.GetStorageById(id)
+	if err != nil {
+		http.Error(c.Writer, "space not found", http.StatusNotFound)
+		return
+	}
+
+	method := c.Request.Method
+	if (method == "COPY" || method == "MOVE") && subPath != "" {
+		if dstID, dstSub, ok := parseDestination(c.Request.Header.Get("Destination")); ok && dstID != id {
+			degradeCrossSpace(c, storage, subPath, dstID, dstSub, method == "MOVE")
+			return
+		}
+	}
+
+	if method == "PROPFIND" && (subPath == "" || subPath == "/") && c.Request.Header.Get("Depth") == "0" {
+		serveSpaceRootPropfind(c, storage, id)
+		return
+	}
+
+	// Fall through to the shared unified-tree handler for anything that
+	// isn't one of the two space-specific behaviors above.
+	c.Request.URL.Path = path.Join(DavPrefix, storage.MountPath, subPath)
+	ServeUnified(c)
+}
+
+func parseSpacePath(p string) (id uint, subPath string, err error) {
+	rest := strings.TrimPrefix(p, SpacesPrefix)
+	idStr, sub, _ := strings.Cut(rest, "/")
+	n, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid space id %q", idStr)
+	}
+	return uint(n), sub, nil
+}
+
+func parseDestination(destination string) (id uint, subPath string, ok bool) {
+	if destination == "" {
+		return 0, "", false
+	}
+	idx := strings.Index(destination, SpacesPrefix)
+	if idx < 0 {
+		return 0, "", false
+	}
+	id, subPath, err := parseSpacePath(destination[idx:])
+	if err != nil {
+		return 0, "", false
+	}
+	return id, subPath, true
+}
+
+type propfindResponse struct {
+	XMLName xml.Name `xml:"D:multistatus"`
+	Xmlns   string   `xml:"xmlns:D,attr"`
+	Href    string   `xml:"D:response>D:href"`
+	Name    string   `xml:"D:response>D:propstat>D:prop>D:displayname"`
+	ResourceType struct {
+		Collection struct{} `xml:"D:collection"`
+	} `xml:"D:response>D:propstat>D:prop>D:resourcetype"`
+	Status string `xml:"D:response>D:propstat>D:status"`
+}
+
+// serveSpaceRootPropfind answers a depth-0 PROPFIND against the space root
+// with a single <D:response> built entirely from the model.Storage row:
+// it never calls into op/fs, so a stalled or erroring driver elsewhere in
+// the tree can't hold up a client that's only asking "does this space
+// exist and is it a collection".
+func serveSpaceRootPropfind(c *gin.Context, storage model.Storage, id uint) {
+	resp := propfindResponse{
+		Xmlns:  "DAV:",
+		Href:   SpacesPrefix + strconv.FormatUint(uint64(id), 10) + "/",
+		Name:   path.Base(storage.MountPath),
+		Status: "HTTP/1.1 200 OK",
+	}
+	c.Writer.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.Writer.WriteHeader(207)
+	_, _ = c.Writer.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(c.Writer).Encode(resp)
+}
+
+// degradeCrossSpace implements the request's "COPY/MOVE between two spaces
+// degrades to server-side copy+delete" rule: the two mounts may be backed
+// by unrelated drivers with no shared rename primitive, so this always
+// reads the source through fs.Copy and, for MOVE, removes the source
+// afterwards rather than failing outright.
+func degradeCrossSpace(c *gin.Context, srcStorage model.Storage, srcSub string, dstID uint, dstSub string, isMove bool) {
+	dstStorage, err := db.GetStorageById(dstID)
+	if err != nil {
+		http.Error(c.Writer, "destination space not found", http.StatusNotFound)
+		return
+	}
+	srcPath := path.Join(srcStorage.MountPath, srcSub)
+	dstPath := path.Join(dstStorage.MountPath, dstSub)
+	if err := fs.Copy(c.Request.Context(), srcPath, dstPath); err != nil {
+		http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if isMove {
+		if err := fs.Remove(c.Request.Context(), srcPath); err != nil {
+			http.Error(c.Writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	c.Writer.WriteHeader(http.StatusCreated)
+}